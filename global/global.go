@@ -0,0 +1,26 @@
+package global
+
+import (
+	"io"
+
+	"github.com/jinzhu/gorm"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/xzy773599929/blog-service/pkg/logger"
+	"github.com/xzy773599929/blog-service/pkg/setting"
+)
+
+var (
+	ServerSetting   *setting.ServerSettingS
+	AppSetting      *setting.AppSettingS
+	DatabaseSetting *setting.DatabaseSettingS
+	LoggerSetting   *setting.LoggerSettingS
+	LokiSetting     *setting.LokiSettingS
+	TracerSetting   *setting.TracerSettingS
+
+	Logger       logger.Logger
+	LokiWriter   *logger.LokiWriter // nil unless LokiSetting.Enable; closed in main's shutdown path
+	Tracer       opentracing.Tracer
+	TracerCloser io.Closer
+	DBEngine     *gorm.DB
+)
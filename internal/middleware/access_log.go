@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/xzy773599929/blog-service/internal/model"
+	"github.com/xzy773599929/blog-service/pkg/setting"
+	"github.com/xzy773599929/blog-service/pkg/tracer"
+	"github.com/xzy773599929/blog-service/pkg/util"
+)
+
+// defaultMaxBodySize caps request body buffering, and the request/response
+// bodies persisted, when AccessLogSettingS.MaxBodySize is unset (its zero
+// value) - a missing config value must still be a bound, not "buffer the
+// whole body". bodyWriter still buffers the whole response; only the
+// persisted copy is capped.
+const defaultMaxBodySize = 64 * 1024 // bytes
+
+// bodyWriter wraps gin's ResponseWriter to also capture everything written
+// to the response, so it can be persisted alongside the request.
+type bodyWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// AccessLog captures method, full URL, client IP, request/response bodies
+// (size-capped), status code, latency, trace_id and user-agent for every
+// request and hands the record to writer for async batch persistence, so
+// the audit trail never blocks the request it describes.
+func AccessLog(settings *setting.AccessLogSettingS, writer *AccessLogWriter) gin.HandlerFunc {
+	exact := make(map[string]struct{}, len(settings.ExcludePaths))
+	var prefixes []string
+	for _, path := range settings.ExcludePaths {
+		if strings.HasSuffix(path, "/*") {
+			prefixes = append(prefixes, strings.TrimSuffix(path, "*"))
+			continue
+		}
+		exact[path] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		if !settings.Enable {
+			c.Next()
+			return
+		}
+		if isExcluded(c.Request.URL.Path, exact, prefixes) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+
+		maxBodySize := settings.MaxBodySize
+		if maxBodySize <= 0 {
+			maxBodySize = defaultMaxBodySize
+		}
+
+		// Cap how much of the body we ever buffer for the audit record: read
+		// at most maxBodySize+1 bytes eagerly, then splice that prefix back
+		// onto whatever's left of the original reader so downstream handlers
+		// still see the full, unmodified body.
+		var reqBody []byte
+		if c.Request.Body != nil {
+			captured, _ := ioutil.ReadAll(io.LimitReader(c.Request.Body, int64(maxBodySize)+1))
+			reqBody = captured
+			c.Request.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(captured), c.Request.Body))
+		}
+
+		respWriter := &bodyWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = respWriter
+
+		c.Next()
+
+		writer.Enqueue(model.ApiAccessLog{
+			Method:     c.Request.Method,
+			Path:       c.Request.URL.String(),
+			ClientIP:   util.ClientIP(c.Request),
+			UserAgent:  c.Request.UserAgent(),
+			TraceID:    tracer.TraceID(c.Request.Context()),
+			ReqBody:    capBody(reqBody, maxBodySize),
+			RespBody:   capBody(respWriter.body.Bytes(), maxBodySize),
+			StatusCode: c.Writer.Status(),
+			LatencyMs:  time.Since(start).Milliseconds(),
+		})
+	}
+}
+
+// isExcluded reports whether path should be skipped: either an exact match
+// in exact, or prefixed by one of prefixes. ExcludePaths entries ending in
+// "/*" (e.g. "/swagger/*") become prefix rules so a mounted sub-tree like a
+// swagger UI is excluded wholesale instead of only its literal root.
+func isExcluded(path string, exact map[string]struct{}, prefixes []string) bool {
+	if _, ok := exact[path]; ok {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func capBody(body []byte, maxSize int) string {
+	if maxSize > 0 && len(body) > maxSize {
+		body = body[:maxSize]
+	}
+	return string(body)
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/xzy773599929/blog-service/global"
+	"github.com/xzy773599929/blog-service/internal/model"
+	"github.com/xzy773599929/blog-service/pkg/logger"
+	"github.com/xzy773599929/blog-service/pkg/setting"
+)
+
+// AccessLogWriter buffers ApiAccessLog records in a bounded channel and
+// flushes them on size or interval, whichever comes first, in one
+// transaction with one INSERT per record (not a multi-row INSERT), so persisting the audit trail never blocks the request it
+// describes. Once the buffer is full the oldest record is dropped to make
+// room, and Dropped() is incremented so operators can alert on it.
+type AccessLogWriter struct {
+	db            *gorm.DB
+	batchSize     int
+	flushInterval time.Duration
+
+	records chan model.ApiAccessLog
+	dropped uint64
+	done    chan struct{}
+}
+
+// defaultFlushInterval backstops AccessLogSettingS.FlushInterval: time.
+// NewTicker panics on a non-positive duration, and a zero value is exactly
+// what an operator gets by leaving FlushInterval unset.
+const defaultFlushInterval = time.Second
+
+func NewAccessLogWriter(db *gorm.DB, settings *setting.AccessLogSettingS) *AccessLogWriter {
+	flushInterval := settings.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = defaultFlushInterval
+	}
+
+	w := &AccessLogWriter{
+		db:            db,
+		batchSize:     settings.BatchSize,
+		flushInterval: flushInterval,
+		records:       make(chan model.ApiAccessLog, settings.BatchSize*4),
+		done:          make(chan struct{}),
+	}
+
+	go w.run()
+	return w
+}
+
+func (w *AccessLogWriter) Enqueue(record model.ApiAccessLog) {
+	select {
+	case w.records <- record:
+	default:
+		select {
+		case <-w.records:
+			atomic.AddUint64(&w.dropped, 1)
+		default:
+		}
+		select {
+		case w.records <- record:
+		default:
+			atomic.AddUint64(&w.dropped, 1)
+		}
+	}
+}
+
+// Dropped returns the number of records dropped so far because the buffer
+// was full.
+func (w *AccessLogWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+func (w *AccessLogWriter) Close() error {
+	close(w.done)
+	return nil
+}
+
+func (w *AccessLogWriter) run() {
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]model.ApiAccessLog, 0, w.batchSize)
+	for {
+		select {
+		case r := <-w.records:
+			batch = append(batch, r)
+			if len(batch) >= w.batchSize {
+				w.flush(batch)
+				batch = make([]model.ApiAccessLog, 0, w.batchSize)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				w.flush(batch)
+				batch = make([]model.ApiAccessLog, 0, w.batchSize)
+			}
+		case <-w.done:
+			if len(batch) > 0 {
+				w.flush(batch)
+			}
+			return
+		}
+	}
+}
+
+func (w *AccessLogWriter) flush(batch []model.ApiAccessLog) {
+	tx := w.db.Begin()
+	for i := range batch {
+		if err := tx.Create(&batch[i]).Error; err != nil {
+			global.Logger.Error("access log batch insert failed", logger.Err(err))
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		global.Logger.Error("access log batch commit failed", logger.Err(err))
+	}
+}
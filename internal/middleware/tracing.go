@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+
+	"github.com/xzy773599929/blog-service/global"
+	"github.com/xzy773599929/blog-service/pkg/logger"
+)
+
+// Tracing extracts any upstream span context from the request headers,
+// starts a span for this request, and attaches it to the request's
+// context.Context alongside a request-scoped logger (global.Logger.
+// WithContext, which picks up this span's trace_id) so downstream
+// handlers and the GORM tracing callbacks in internal/model all log
+// through the same logger and the same span.
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tracer := opentracing.GlobalTracer()
+		spanCtx, _ := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(c.Request.Header))
+		span := tracer.StartSpan(c.Request.Method+" "+c.FullPath(), ext.RPCServerOption(spanCtx))
+		defer span.Finish()
+
+		ext.HTTPMethod.Set(span, c.Request.Method)
+		ext.HTTPUrl.Set(span, c.Request.URL.String())
+
+		ctx := opentracing.ContextWithSpan(c.Request.Context(), span)
+		ctx = logger.NewContext(ctx, global.Logger.WithContext(ctx))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		ext.HTTPStatusCode.Set(span, uint16(c.Writer.Status()))
+		if len(c.Errors) > 0 {
+			ext.Error.Set(span, true)
+		}
+	}
+}
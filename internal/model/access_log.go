@@ -0,0 +1,21 @@
+package model
+
+// ApiAccessLog is the audit-trail record written by
+// internal/middleware.AccessLog for every request, independent of the
+// rolling log files.
+type ApiAccessLog struct {
+	Model
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	ClientIP   string `json:"client_ip"`
+	UserAgent  string `json:"user_agent"`
+	TraceID    string `json:"trace_id"`
+	ReqBody    string `json:"req_body"`
+	RespBody   string `json:"resp_body"`
+	StatusCode int    `json:"status_code"`
+	LatencyMs  int64  `json:"latency_ms"`
+}
+
+func (a ApiAccessLog) TableName() string {
+	return "blog_api_access_log"
+}
@@ -1,14 +1,28 @@
 package model
 
 import (
+	"context"
 	"fmt"
+	"time"
+
 	"github.com/jinzhu/gorm"
 	_ "github.com/jinzhu/gorm/dialects/mysql"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+
 	"github.com/xzy773599929/blog-service/global"
+	"github.com/xzy773599929/blog-service/pkg/logger"
 	"github.com/xzy773599929/blog-service/pkg/setting"
-	"time"
 )
 
+// tracingCtxKey is the scope value under which a traced *gorm.DB (obtained
+// via db.Set(tracingCtxKey, ctx)) stashes the request context, so the
+// tracing callbacks below can start a child span per statement.
+const tracingCtxKey = "_tracing_ctx"
+const tracingSpanKey = "_tracing_span"
+const tracingStartKey = "_tracing_start"
+const tracingOperationKey = "_tracing_operation"
+
 const (
 	STATE_OPEN  = 1
 	STATE_CLOSE = 0
@@ -40,17 +54,112 @@ func NewDBEngine(databaseSetting *setting.DatabaseSettingS) (*gorm.DB, error) {
 
 	if global.ServerSetting.RunMode == "debug" {
 		db.LogMode(true)
+		db.SetLogger(logger.NewGormAdapter(global.Logger))
 	}
 	db.SingularTable(true)
 	db.Callback().Create().Replace("gorm:update_time_stamp", updateTimeStampForCreateCallback)
 	db.Callback().Update().Replace("gorm:update_time_stamp",updateTimeStampForUpdateCallback)
 	db.Callback().Delete().Replace("gorm:delete",deleteCallback)
+	db.Callback().Create().Before("gorm:create").Register("tracing:before_create", tracingBeforeCallback("INSERT"))
+	db.Callback().Create().After("gorm:create").Register("tracing:after_create", tracingAfterCallback)
+	db.Callback().Query().Before("gorm:query").Register("tracing:before_query", tracingBeforeCallback("SELECT"))
+	db.Callback().Query().After("gorm:query").Register("tracing:after_query", tracingAfterCallback)
+	db.Callback().Update().Before("gorm:update").Register("tracing:before_update", tracingBeforeCallback("UPDATE"))
+	db.Callback().Update().After("gorm:update").Register("tracing:after_update", tracingAfterCallback)
+	db.Callback().Delete().Before("gorm:delete").Register("tracing:before_delete", tracingBeforeCallback("DELETE"))
+	db.Callback().Delete().After("gorm:delete").Register("tracing:after_delete", tracingAfterCallback)
 	db.DB().SetMaxIdleConns(databaseSetting.MaxIdleConns)
 	db.DB().SetMaxOpenConns(databaseSetting.MaxOpenConns)
 
 	return db, nil
 }
 
+// WithContext returns a *gorm.DB scoped to ctx, so the tracing callbacks
+// below can start each statement's span as a child of ctx's active span.
+// Callers obtain a per-request DB handle with model.WithContext(db, ctx)
+// instead of using the global *gorm.DB directly.
+func WithContext(db *gorm.DB, ctx context.Context) *gorm.DB {
+	return db.Set(tracingCtxKey, ctx)
+}
+
+//以sql.query为名，为每条语句起一个子span，记录表名与操作类型
+func tracingBeforeCallback(operation string) func(scope *gorm.Scope) {
+	return func(scope *gorm.Scope) {
+		scope.Set(tracingStartKey, time.Now())
+		scope.Set(tracingOperationKey, operation)
+
+		ctxValue, ok := scope.Get(tracingCtxKey)
+		if !ok {
+			return
+		}
+		ctx, ok := ctxValue.(context.Context)
+		if !ok {
+			return
+		}
+
+		span, _ := opentracing.StartSpanFromContext(ctx, "sql.query")
+		span.SetTag("db.table", scope.TableName())
+		span.SetTag("db.operation", operation)
+		scope.Set(tracingSpanKey, span)
+	}
+}
+
+//语句执行完毕后，补上受影响行数与错误标记，记录一条携带trace_id的sql日志，随后结束span
+func tracingAfterCallback(scope *gorm.Scope) {
+	if spanValue, ok := scope.Get(tracingSpanKey); ok {
+		if span, ok := spanValue.(opentracing.Span); ok {
+			defer span.Finish()
+			span.SetTag("db.rows_affected", scope.DB().RowsAffected)
+			if scope.HasError() {
+				ext.Error.Set(span, true)
+			}
+		}
+	}
+
+	logSQL(scope)
+}
+
+// logSQL emits the just-completed statement through the request-scoped
+// logger carried by the context that WithContext stashed in scope (or
+// global.Logger when that context carries none), so the resulting line
+// carries this request's trace_id - the correlation GormAdapter can't
+// provide, since gorm's LogWriter interface never passes Print a context.
+// Silently does nothing outside a traced request (no tracingCtxKey means no
+// context to log against).
+func logSQL(scope *gorm.Scope) {
+	ctxValue, ok := scope.Get(tracingCtxKey)
+	if !ok {
+		return
+	}
+	ctx, ok := ctxValue.(context.Context)
+	if !ok {
+		return
+	}
+
+	var duration time.Duration
+	if startValue, ok := scope.Get(tracingStartKey); ok {
+		if start, ok := startValue.(time.Time); ok {
+			duration = time.Since(start)
+		}
+	}
+	operation, _ := scope.Get(tracingOperationKey)
+
+	fields := []logger.Field{
+		logger.Any("operation", operation),
+		logger.String("table", scope.TableName()),
+		logger.String("sql", scope.SQL),
+		logger.Duration("duration", duration),
+		logger.Int64("rows", scope.DB().RowsAffected),
+	}
+
+	l := logger.FromContext(ctx, global.Logger)
+	if scope.HasError() && scope.Error() != gorm.ErrRecordNotFound {
+		l.Error("sql.query", append(fields, logger.Err(scope.Error()))...)
+		return
+	}
+	l.Info("sql.query", fields...)
+}
+
 //新增行为的回调
 func updateTimeStampForCreateCallback(scope *gorm.Scope)  {
 	if !scope.HasError() {
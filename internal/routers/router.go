@@ -0,0 +1,39 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/xzy773599929/blog-service/global"
+	"github.com/xzy773599929/blog-service/internal/middleware"
+	"github.com/xzy773599929/blog-service/internal/model"
+	"github.com/xzy773599929/blog-service/pkg/app"
+	"github.com/xzy773599929/blog-service/pkg/errcode"
+)
+
+// NewRouter builds the gin engine and wires in the tracing and access-log
+// middleware alongside a health check that pings the database through
+// database/sql (no GORM callbacks run, so it records no sql.query span).
+func NewRouter(accessLogWriter *middleware.AccessLogWriter) *gin.Engine {
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.Use(middleware.Tracing())
+	r.Use(middleware.AccessLog(&global.ServerSetting.AccessLog, accessLogWriter))
+
+	r.GET("/healthz", healthCheck)
+	r.NoRoute(notFound)
+
+	return r
+}
+
+func healthCheck(c *gin.Context) {
+	db := model.WithContext(global.DBEngine, c.Request.Context())
+	if err := db.DB().Ping(); err != nil {
+		app.NewResponse(c).WithTrace(errcode.ServerError.WithDetails(err.Error()))
+		return
+	}
+	app.NewResponse(c).ToResponse(gin.H{"status": "up"})
+}
+
+func notFound(c *gin.Context) {
+	app.NewResponse(c).WithTrace(errcode.NotFound)
+}
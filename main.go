@@ -1,19 +1,125 @@
 package main
 
 import (
-	"github.com/xzy773599929/blog-service/internal/routers"
+	"io"
 	"net/http"
+	"os"
 	"time"
+
+	"github.com/xzy773599929/blog-service/global"
+	"github.com/xzy773599929/blog-service/internal/middleware"
+	"github.com/xzy773599929/blog-service/internal/model"
+	"github.com/xzy773599929/blog-service/internal/routers"
+	"github.com/xzy773599929/blog-service/pkg/logger"
+	"github.com/xzy773599929/blog-service/pkg/setting"
+	"github.com/xzy773599929/blog-service/pkg/tracer"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
+func init() {
+	if err := setupSetting(); err != nil {
+		panic(err)
+	}
+	setupLogger()
+	if err := setupTracer(); err != nil {
+		panic(err)
+	}
+	if err := setupDBEngine(); err != nil {
+		panic(err)
+	}
+}
+
 func main() {
-	router := routers.NewRouter()
+	defer global.TracerCloser.Close()
+	if global.LokiWriter != nil {
+		defer global.LokiWriter.Close()
+	}
+
+	accessLogWriter := middleware.NewAccessLogWriter(global.DBEngine, &global.ServerSetting.AccessLog)
+	defer accessLogWriter.Close()
+
+	router := routers.NewRouter(accessLogWriter)
 	s := &http.Server{
-		Addr: ":8080",
-		Handler: router,
-		ReadTimeout: 10 * time.Second,
-		WriteTimeout: 10 * time.Second,
+		Addr:           ":8080",
+		Handler:        router,
+		ReadTimeout:    10 * time.Second,
+		WriteTimeout:   10 * time.Second,
 		MaxHeaderBytes: 1 << 20,
 	}
 	_ = s.ListenAndServe()
 }
+
+func setupSetting() error {
+	s, err := setting.NewSetting("configs")
+	if err != nil {
+		return err
+	}
+	if err := s.ReadSection("Server", &global.ServerSetting); err != nil {
+		return err
+	}
+	if err := s.ReadSection("App", &global.AppSetting); err != nil {
+		return err
+	}
+	if err := s.ReadSection("Database", &global.DatabaseSetting); err != nil {
+		return err
+	}
+	if err := s.ReadSection("Logger", &global.LoggerSetting); err != nil {
+		return err
+	}
+	if err := s.ReadSection("Loki", &global.LokiSetting); err != nil {
+		return err
+	}
+	if err := s.ReadSection("Tracer", &global.TracerSetting); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// setupTracer starts the Jaeger tracer and registers it as the opentracing
+// global tracer used by internal/middleware.Tracing and the GORM callbacks.
+func setupTracer() error {
+	t, closer, err := tracer.NewJaegerTracer(global.TracerSetting)
+	if err != nil {
+		return err
+	}
+
+	global.Tracer = t
+	global.TracerCloser = closer
+	return nil
+}
+
+// setupDBEngine opens the shared *gorm.DB used by model queries and, via
+// middleware.NewAccessLogWriter, the access-log audit trail.
+func setupDBEngine() error {
+	db, err := model.NewDBEngine(global.DatabaseSetting)
+	if err != nil {
+		return err
+	}
+
+	global.DBEngine = db
+	return nil
+}
+
+// setupLogger wires global.Logger to every configured sink: stdout always,
+// a size/age-rotated file when Logger.RotateFile is enabled, and a Loki
+// push sink when Loki.Enable is set.
+func setupLogger() {
+	writers := []io.Writer{os.Stdout}
+	if global.LoggerSetting.RotateFile.Enable {
+		rf := global.LoggerSetting.RotateFile
+		writers = append(writers, &lumberjack.Logger{
+			Filename:   rf.SavePath + "/" + rf.FileName + rf.FileExt,
+			MaxSize:    rf.MaxSize,
+			MaxBackups: rf.MaxBackups,
+			MaxAge:     rf.MaxAge,
+			Compress:   rf.Compress,
+		})
+	}
+	if global.LokiSetting.Enable {
+		global.LokiWriter = logger.NewLokiWriter(global.LokiSetting)
+		writers = append(writers, global.LokiWriter)
+	}
+
+	global.Logger = logger.NewLogger(global.LoggerSetting, writers...)
+}
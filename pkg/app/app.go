@@ -1,9 +1,14 @@
 package app
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	tracelog "github.com/opentracing/opentracing-go/log"
+
 	"github.com/xzy773599929/blog-service/pkg/errcode"
-	"net/http"
 )
 
 type Response struct {
@@ -51,4 +56,16 @@ func (r *Response) ToErrorResponse(err *errcode.Error)  {
 	}
 
 	r.Ctx.JSON(err.StatusCode(), response)
+}
+
+// WithTrace records err on the request's active span, if one is present,
+// before rendering the usual error response - so a span in Jaeger shows why
+// the request it belongs to failed.
+func (r *Response) WithTrace(err *errcode.Error) {
+	if span := opentracing.SpanFromContext(r.Ctx.Request.Context()); span != nil {
+		ext.Error.Set(span, true)
+		span.LogFields(tracelog.Int("code", err.Code()), tracelog.String("message", err.Msg()))
+	}
+
+	r.ToErrorResponse(err)
 }
\ No newline at end of file
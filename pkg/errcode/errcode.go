@@ -0,0 +1,72 @@
+package errcode
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Error is a typed API error: a stable numeric Code for clients to switch
+// on, a human Msg, and optional Details (e.g. validation failures).
+type Error struct {
+	code    int
+	msg     string
+	details []string
+}
+
+var codes = map[int]string{}
+
+// NewError registers a new error code; it panics on a duplicate code so a
+// copy-pasted code collision is caught at init time instead of at runtime.
+func NewError(code int, msg string) *Error {
+	if _, ok := codes[code]; ok {
+		panic(fmt.Sprintf("errcode: code %d already registered", code))
+	}
+	codes[code] = msg
+	return &Error{code: code, msg: msg}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("code: %d, msg: %s", e.code, e.msg)
+}
+
+func (e *Error) Code() int {
+	return e.code
+}
+
+func (e *Error) Msg() string {
+	return e.msg
+}
+
+func (e *Error) Details() []string {
+	return e.details
+}
+
+// WithDetails returns a copy of e carrying the given details, leaving the
+// shared registered Error untouched.
+func (e *Error) WithDetails(details ...string) *Error {
+	newError := *e
+	newError.details = append([]string{}, details...)
+	return &newError
+}
+
+// StatusCode maps this error's code to the HTTP status it should render as.
+func (e *Error) StatusCode() int {
+	switch e.code {
+	case Success.Code():
+		return http.StatusOK
+	case InvalidParams.Code():
+		return http.StatusBadRequest
+	case NotFound.Code():
+		return http.StatusNotFound
+	case ServerError.Code():
+		return http.StatusInternalServerError
+	}
+	return http.StatusInternalServerError
+}
+
+var (
+	Success       = NewError(0, "success")
+	ServerError   = NewError(10000000, "server error")
+	InvalidParams = NewError(10000001, "invalid params")
+	NotFound      = NewError(10000002, "not found")
+)
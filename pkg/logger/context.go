@@ -0,0 +1,23 @@
+package logger
+
+import "context"
+
+type ctxKey struct{}
+
+// NewContext returns a copy of ctx carrying l, retrievable with FromContext.
+// internal/middleware.Tracing uses this to hand every downstream consumer -
+// handlers and the GORM tracing callbacks alike - the same request-scoped
+// logger it built via Logger.WithContext, so every log line along the way
+// carries this request's trace_id.
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext returns the Logger stored in ctx by NewContext, or fallback
+// if ctx carries none (e.g. a background job with no active request).
+func FromContext(ctx context.Context, fallback Logger) Logger {
+	if l, ok := ctx.Value(ctxKey{}).(Logger); ok {
+		return l
+	}
+	return fallback
+}
@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"fmt"
+)
+
+// GormAdapter implements gorm's LogWriter interface (Print(v ...interface{}))
+// so non-SQL gorm messages (connection setup, migrations, and the like) go
+// through the same Logger pipeline - sinks, encoders - as the rest of the
+// app. Per-statement SQL logs are handled elsewhere, by the tracing
+// callbacks in internal/model: unlike those callbacks, Print never receives
+// a context, so it has no trace_id to attach and "sql" entries are skipped
+// here to avoid logging every query twice. Statements run on a *gorm.DB
+// that did not go through model.WithContext are logged by neither path.
+type GormAdapter struct {
+	logger Logger
+}
+
+func NewGormAdapter(l Logger) *GormAdapter {
+	return &GormAdapter{logger: l}
+}
+
+// Print receives gorm's log tuples. "sql" entries are skipped (see the
+// GormAdapter doc comment); anything else is a generic gorm message and is
+// logged as-is.
+func (g *GormAdapter) Print(v ...interface{}) {
+	if len(v) < 2 {
+		return
+	}
+	if v[0] == "sql" {
+		return
+	}
+
+	g.logger.Warn(fmt.Sprint(v[2:]...))
+}
@@ -2,18 +2,39 @@ package logger
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"runtime"
 	"time"
+
+	"github.com/xzy773599929/blog-service/pkg/setting"
+	"github.com/xzy773599929/blog-service/pkg/tracer"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 type Level int8
 
+// Fields is the legacy key/value bag accepted by WithFields; hot paths
+// should prefer the typed Field API below to avoid the map allocation.
 type Fields map[string]interface{}
 
+// Field is a single typed key/value pair, analogous to zap.Field, passed
+// directly to a leveled log call instead of going through WithFields.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+func String(key, val string) Field                 { return Field{Key: key, Value: val} }
+func Int(key string, val int) Field                { return Field{Key: key, Value: val} }
+func Int64(key string, val int64) Field            { return Field{Key: key, Value: val} }
+func Float64(key string, val float64) Field        { return Field{Key: key, Value: val} }
+func Bool(key string, val bool) Field              { return Field{Key: key, Value: val} }
+func Any(key string, val interface{}) Field        { return Field{Key: key, Value: val} }
+func Err(err error) Field                          { return Field{Key: "error", Value: err} }
+func Duration(key string, val time.Duration) Field { return Field{Key: key, Value: val} }
+
 const (
 	LevelDebug Level = iota
 	LevelInfo
@@ -41,55 +62,170 @@ func (l Level) String() string {
 	return ""
 }
 
-type Logger struct {
-	newLogger *log.Logger
-	ctx context.Context
-	level Level
-	fields Fields
+func (l Level) zapLevel() zapcore.Level {
+	switch l {
+	case LevelDebug:
+		return zapcore.DebugLevel
+	case LevelInfo:
+		return zapcore.InfoLevel
+	case LevelWarn:
+		return zapcore.WarnLevel
+	case LevelError:
+		return zapcore.ErrorLevel
+	case LevelFatal:
+		return zapcore.FatalLevel
+	case LevelPanic:
+		return zapcore.PanicLevel
+	}
+	return zapcore.InfoLevel
+}
+
+// ParseLevel converts a config string (as set in setting.LoggerSettingS.Level)
+// into a Level, defaulting to LevelInfo when it isn't recognised.
+func ParseLevel(s string) Level {
+	switch s {
+	case "debug":
+		return LevelDebug
+	case "info":
+		return LevelInfo
+	case "warn":
+		return LevelWarn
+	case "error":
+		return LevelError
+	case "fatal":
+		return LevelFatal
+	case "panic":
+		return LevelPanic
+	}
+	return LevelInfo
+}
+
+// Logger is the logging surface every package in this repo depends on.
+// global.Logger holds one of these so callers stay insulated from the
+// concrete encoder/sink wiring picked in NewLogger.
+type Logger interface {
+	Debug(msg string, fields ...Field)
+	Debugf(format string, v ...interface{})
+	Info(msg string, fields ...Field)
+	Infof(format string, v ...interface{})
+	Warn(msg string, fields ...Field)
+	Warnf(format string, v ...interface{})
+	Error(msg string, fields ...Field)
+	Errorf(format string, v ...interface{})
+	Fatal(msg string, fields ...Field)
+	Fatalf(format string, v ...interface{})
+	Panic(msg string, fields ...Field)
+	Panicf(format string, v ...interface{})
+
+	WithFields(f Fields) Logger
+	WithContext(ctx context.Context) Logger
+	WithCaller(skip int) Logger
+	WithCallersFrames() Logger
+}
+
+// zapLogger is the zap-backed Logger implementation. Its encoder is chosen
+// once in NewLogger from LoggerSettingS.Mode: "dev" gets a colored, human
+// readable console encoder, anything else gets compact JSON.
+type zapLogger struct {
+	zap     *zap.Logger
+	ctx     context.Context
+	fields  Fields
 	callers []string
 }
 
-func NewLogger(w io.Writer, prefix string, flag int) *Logger {
-	//参数w设置日志信息写入的目的地。参数prefix会添加到生成的每一条日志前面。参数flag定义日志的属性（时间、文件等等）
-	l := log.New(w, prefix, flag)
-	return &Logger{newLogger: l}
+// NewLogger builds a Logger fanned out across every writer passed in (e.g.
+// os.Stdout alongside a lumberjack-backed rotating file or the Loki sink).
+// settings.Level sets the minimum level that reaches any sink; settings.Mode
+// picks the encoder.
+func NewLogger(settings *setting.LoggerSettingS, writers ...io.Writer) Logger {
+	minLevel := LevelInfo
+	mode := "prod"
+	if settings != nil {
+		minLevel = ParseLevel(settings.Level)
+		mode = settings.Mode
+	}
+
+	var encoder zapcore.Encoder
+	if mode == "dev" {
+		encoder = zapcore.NewConsoleEncoder(developmentEncoderConfig())
+	} else {
+		encoder = zapcore.NewJSONEncoder(productionEncoderConfig())
+	}
+
+	enabler := zap.NewAtomicLevelAt(minLevel.zapLevel())
+	cores := make([]zapcore.Core, 0, len(writers))
+	for _, w := range writers {
+		cores = append(cores, zapcore.NewCore(encoder, zapcore.AddSync(w), enabler))
+	}
+
+	zl := zap.New(zapcore.NewTee(cores...), zap.AddCaller(), zap.AddCallerSkip(1))
+	l := Logger(&zapLogger{zap: zl})
+
+	if settings != nil {
+		base := Fields{}
+		if settings.App != "" {
+			base["app"] = settings.App
+		}
+		if settings.Env != "" {
+			base["env"] = settings.Env
+		}
+		if len(base) > 0 {
+			l = l.WithFields(base)
+		}
+	}
+
+	return l
+}
+
+func productionEncoderConfig() zapcore.EncoderConfig {
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.TimeKey = "time"
+	cfg.EncodeTime = zapcore.ISO8601TimeEncoder
+	cfg.CallerKey = "caller"
+	cfg.MessageKey = "message"
+	return cfg
 }
 
-func (l *Logger)clone() *Logger {
-	nl := *l
-	return &nl
+func developmentEncoderConfig() zapcore.EncoderConfig {
+	cfg := zap.NewDevelopmentEncoderConfig()
+	cfg.EncodeTime = zapcore.ISO8601TimeEncoder
+	cfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	cfg.EncodeCaller = zapcore.ShortCallerEncoder
+	return cfg
 }
 
-//设置日志等级
-func (l *Logger) WithLevel(lvl Level) *Logger {
-	ll := l.clone()
-	ll.level = lvl
-	return ll
+func (l *zapLogger) clone() *zapLogger {
+	nl := *l
+	return &nl
 }
 
 //设置日志公共字段
-func (l *Logger) WithFields(f Fields) *Logger {
+func (l *zapLogger) WithFields(f Fields) Logger {
 	ll := l.clone()
-	if ll.fields == nil {
-		ll.fields = make(Fields)
+	merged := make(Fields, len(ll.fields)+len(f))
+	for k, v := range ll.fields {
+		merged[k] = v
 	}
 	for k, v := range f {
-		ll.fields[k] = v
+		merged[k] = v
 	}
+	ll.fields = merged
 	return ll
 }
 
-
-//设置日志上下文属性
-func (l *Logger) WithContext(ctx context.Context) *Logger {
+//设置日志上下文属性，若ctx携带活跃的tracing span，则自动补充trace_id字段
+func (l *zapLogger) WithContext(ctx context.Context) Logger {
 	ll := l.clone()
 	ll.ctx = ctx
+	if traceID := tracer.TraceID(ctx); traceID != "" {
+		return ll.WithFields(Fields{"trace_id": traceID})
+	}
 	return ll
 }
 
 //设置当前某一层调用栈的信息(程序计数器、文件信息和行号)
 //实参skip为上溯的栈帧数，0表示Caller的调用者（Caller所在的调用栈）
-func (l *Logger) WithCaller(skip int) *Logger {
+func (l *zapLogger) WithCaller(skip int) Logger {
 	ll := l.clone()
 	//函数的返回值为调用栈标识符、文件名、该调用在文件中的行号。如果无法获得信息，ok会被设为false。
 	pc, file, line, ok := runtime.Caller(skip)
@@ -104,7 +240,7 @@ func (l *Logger) WithCaller(skip int) *Logger {
 }
 
 //设置当前的整个调用栈信息
-func (l *Logger) WithCallersFrames() *Logger {
+func (l *zapLogger) WithCallersFrames() Logger {
 	maxCallerDepth := 25
 	minCallerDepth := 1
 	callers := []string{}
@@ -119,7 +255,7 @@ func (l *Logger) WithCallersFrames() *Logger {
 	//Next返回下一个调用方的帧信息。
 	//如果more为false，则不再有调用方（帧值有效）。
 	for frame, more := frames.Next(); more; frame, more = frames.Next() {
-		callers = append(callers, fmt.Sprintf("%s: %d %s",frame.File, frame.Line, frame.Function))
+		callers = append(callers, fmt.Sprintf("%s: %d %s", frame.File, frame.Line, frame.Function))
 		if !more {
 			break
 		}
@@ -130,89 +266,49 @@ func (l *Logger) WithCallersFrames() *Logger {
 	return ll
 }
 
-//日志格式化
-func (l *Logger) JSONFormat(message string) map[string]interface{} {
-	data := make(Fields, len(l.fields)+4)
-	data["level"] = l.level.String()
-	data["time"] = time.Now().Local().UnixNano()
-	data["message"] = message
-	data["callers"] = l.callers
-	if len(l.fields) > 0 {
-		for k, v := range l.fields {
-			if _, ok := data[k]; !ok {
-				data[k] = v
-			}
-		}
+// zapFields merges the logger's accumulated fields/callers with the fields
+// passed directly to this call.
+func (l *zapLogger) zapFields(extra []Field) []zap.Field {
+	zf := make([]zap.Field, 0, len(l.fields)+len(extra)+1)
+	if len(l.callers) > 0 {
+		zf = append(zf, zap.Strings("callers", l.callers))
 	}
-
-	return data
-}
-
-//日志输出
-func (l *Logger) Output(message string) {
-	body, _ := json.Marshal(l.JSONFormat(message))
-	content := string(body)
-	switch l.level {
-	case LevelDebug:
-		l.newLogger.Print(content)
-	case LevelInfo:
-		l.newLogger.Print(content)
-	case LevelWarn:
-		l.newLogger.Print(content)
-	case LevelError:
-		l.newLogger.Print(content)
-	case LevelFatal:
-		l.newLogger.Print(content)
-	case LevelPanic:
-		l.newLogger.Print(content)
+	for k, v := range l.fields {
+		zf = append(zf, zap.Any(k, v))
 	}
+	for _, f := range extra {
+		zf = append(zf, zap.Any(f.Key, f.Value))
+	}
+	return zf
 }
 
-//日志分级输出,以及格式化输出
-func (l *Logger) Debug(v ...interface{}) {
-	l.WithLevel(LevelDebug).Output(fmt.Sprint(v...))
-}
-
-func (l *Logger) Debugf(format string, v ...interface{}) {
-	l.WithLevel(LevelDebug).Output(fmt.Sprintf(format, v...))
-}
-
-func (l *Logger) Info(v ...interface{}) {
-	l.WithLevel(LevelInfo).Output(fmt.Sprint(v...))
-}
-
-func (l *Logger) Infof(format string, v ...interface{}) {
-	l.WithLevel(LevelInfo).Output(fmt.Sprintf(format, v...))
-}
-
-func (l *Logger) Fatal(v ...interface{}) {
-	l.WithLevel(LevelFatal).Output(fmt.Sprint(v...))
-}
-
-func (l *Logger) Fatalf(format string, v ...interface{}) {
-	l.WithLevel(LevelFatal).Output(fmt.Sprintf(format, v...))
+//日志分级输出；Fatal/Panic借助zap自身的Fatal/Panic语义，分别触发os.Exit(1)和panic
+func (l *zapLogger) Debug(msg string, fields ...Field) { l.zap.Debug(msg, l.zapFields(fields)...) }
+func (l *zapLogger) Debugf(format string, v ...interface{}) {
+	l.zap.Debug(fmt.Sprintf(format, v...), l.zapFields(nil)...)
 }
 
-func (l *Logger) Warn(v ...interface{}) {
-	l.WithLevel(LevelWarn).Output(fmt.Sprint(v...))
+func (l *zapLogger) Info(msg string, fields ...Field) { l.zap.Info(msg, l.zapFields(fields)...) }
+func (l *zapLogger) Infof(format string, v ...interface{}) {
+	l.zap.Info(fmt.Sprintf(format, v...), l.zapFields(nil)...)
 }
 
-func (l *Logger) Warnf(format string, v ...interface{}) {
-	l.WithLevel(LevelWarn).Output(fmt.Sprintf(format, v...))
+func (l *zapLogger) Warn(msg string, fields ...Field) { l.zap.Warn(msg, l.zapFields(fields)...) }
+func (l *zapLogger) Warnf(format string, v ...interface{}) {
+	l.zap.Warn(fmt.Sprintf(format, v...), l.zapFields(nil)...)
 }
 
-func (l *Logger) Error(v ...interface{}) {
-	l.WithLevel(LevelError).Output(fmt.Sprint(v...))
+func (l *zapLogger) Error(msg string, fields ...Field) { l.zap.Error(msg, l.zapFields(fields)...) }
+func (l *zapLogger) Errorf(format string, v ...interface{}) {
+	l.zap.Error(fmt.Sprintf(format, v...), l.zapFields(nil)...)
 }
 
-func (l *Logger) Errorf(format string, v ...interface{}) {
-	l.WithLevel(LevelError).Output(fmt.Sprintf(format, v...))
+func (l *zapLogger) Fatal(msg string, fields ...Field) { l.zap.Fatal(msg, l.zapFields(fields)...) }
+func (l *zapLogger) Fatalf(format string, v ...interface{}) {
+	l.zap.Fatal(fmt.Sprintf(format, v...), l.zapFields(nil)...)
 }
 
-func (l *Logger) Panic(v ...interface{}) {
-	l.WithLevel(LevelPanic).Output(fmt.Sprint(v...))
+func (l *zapLogger) Panic(msg string, fields ...Field) { l.zap.Panic(msg, l.zapFields(fields)...) }
+func (l *zapLogger) Panicf(format string, v ...interface{}) {
+	l.zap.Panic(fmt.Sprintf(format, v...), l.zapFields(nil)...)
 }
-
-func (l *Logger) Panicf(format string, v ...interface{}) {
-	l.WithLevel(LevelPanic).Output(fmt.Sprintf(format, v...))
-}
\ No newline at end of file
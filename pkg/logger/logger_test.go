@@ -0,0 +1,50 @@
+package logger
+
+import "testing"
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Level
+	}{
+		{"debug", LevelDebug},
+		{"info", LevelInfo},
+		{"warn", LevelWarn},
+		{"error", LevelError},
+		{"fatal", LevelFatal},
+		{"panic", LevelPanic},
+		{"", LevelInfo},
+		{"bogus", LevelInfo},
+	}
+
+	for _, tt := range tests {
+		if got := ParseLevel(tt.in); got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWithFieldsMerges(t *testing.T) {
+	base := &zapLogger{fields: Fields{"app": "blog-service", "env": "dev"}}
+
+	merged := base.WithFields(Fields{"env": "prod", "request_id": "abc"}).(*zapLogger)
+
+	if merged.fields["app"] != "blog-service" {
+		t.Errorf("expected app to survive the merge, got %v", merged.fields["app"])
+	}
+	if merged.fields["env"] != "prod" {
+		t.Errorf("expected env to be overridden to prod, got %v", merged.fields["env"])
+	}
+	if merged.fields["request_id"] != "abc" {
+		t.Errorf("expected request_id to be added, got %v", merged.fields["request_id"])
+	}
+
+	// The receiver must stay untouched - With* calls are meant to be
+	// non-mutating so a shared base logger can be fanned out safely.
+	if _, ok := base.fields["request_id"]; ok {
+		t.Errorf("WithFields must not mutate the receiver's fields")
+	}
+	if base.fields["env"] != "dev" {
+		t.Errorf("WithFields must not mutate the receiver's fields, env changed to %v", base.fields["env"])
+	}
+}
@@ -0,0 +1,60 @@
+package logger
+
+import "testing"
+
+func TestExtractLabels(t *testing.T) {
+	line := `{"app":"blog-service","env":"dev","level":"info","message":"hello"}`
+
+	got := extractLabels(line, []string{"app", "env", "level", "missing"})
+
+	want := map[string]string{"app": "blog-service", "env": "dev", "level": "info"}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("extractLabels()[%q] = %q, want %q", k, got[k], v)
+		}
+	}
+	if _, ok := got["missing"]; ok {
+		t.Errorf("extractLabels() should omit keys absent from the line, got %v", got["missing"])
+	}
+}
+
+func TestBucketKeyIsStableAndDistinguishesValues(t *testing.T) {
+	keys := []string{"app", "level"}
+
+	a := bucketKey(keys, map[string]string{"app": "blog-service", "level": "info"})
+	aAgain := bucketKey(keys, map[string]string{"app": "blog-service", "level": "info"})
+	b := bucketKey(keys, map[string]string{"app": "blog-service", "level": "error"})
+
+	if a != aAgain {
+		t.Errorf("bucketKey() is not stable for identical inputs: %q != %q", a, aAgain)
+	}
+	if a == b {
+		t.Errorf("bucketKey() should differ when a label value differs, got %q for both", a)
+	}
+}
+
+func TestGroupByLabelsGroupsEntriesByLabelCombination(t *testing.T) {
+	labelKeys := []string{"level"}
+
+	batch := []lokiEntry{
+		{ts: 1, line: `{"level":"info","message":"a"}`},
+		{ts: 2, line: `{"level":"info","message":"b"}`},
+		{ts: 3, line: `{"level":"error","message":"c"}`},
+	}
+
+	buckets := groupByLabels(batch, labelKeys)
+
+	if len(buckets) != 2 {
+		t.Fatalf("expected 2 buckets (info, error), got %d", len(buckets))
+	}
+
+	infoKey := bucketKey(labelKeys, map[string]string{"level": "info"})
+	if len(buckets[infoKey].values) != 2 {
+		t.Errorf("expected 2 entries in the info bucket, got %d", len(buckets[infoKey].values))
+	}
+
+	errorKey := bucketKey(labelKeys, map[string]string{"level": "error"})
+	if len(buckets[errorKey].values) != 1 {
+		t.Errorf("expected 1 entry in the error bucket, got %d", len(buckets[errorKey].values))
+	}
+}
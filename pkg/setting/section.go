@@ -0,0 +1,103 @@
+package setting
+
+import "time"
+
+type ServerSettingS struct {
+	RunMode      string
+	HttpPort     int
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	AccessLog AccessLogSettingS
+}
+
+// AccessLogSettingS toggles the audit-trail access-log middleware and tunes
+// its async batch writer: ExcludePaths skips noisy/irrelevant routes (health
+// checks, metrics, swagger) - entries are matched exactly unless they end in
+// "/*", which excludes the whole sub-tree (e.g. "/swagger/*"). MaxBodySize
+// caps how much of the request/response body is persisted per record
+// (falls back to a built-in default when unset, never "unbounded").
+type AccessLogSettingS struct {
+	Enable       bool
+	ExcludePaths []string
+	MaxBodySize  int // bytes
+
+	BatchSize     int
+	FlushInterval time.Duration
+}
+
+type AppSettingS struct {
+	DefaultPageSize int
+	MaxPageSize     int
+}
+
+type DatabaseSettingS struct {
+	DBType       string
+	Username     string
+	Password     string
+	Host         string
+	DBName       string
+	Charset      string
+	ParseTime    bool
+	MaxIdleConns int
+	MaxOpenConns int
+}
+
+// LoggerSettingS configures the structured logger: its dev/prod encoder
+// mode, the minimum level that reaches any sink, and the per-sink options
+// below (stdout is always on, the rest are opt-in).
+type LoggerSettingS struct {
+	Mode  string // "dev" or "prod"
+	Level string // debug|info|warn|error|fatal|panic
+
+	// App and Env, when set, are attached as base fields on every log line
+	// (and, in turn, are what LokiSettingS.Labels lifts into stream labels).
+	App string
+	Env string
+
+	RotateFile RotateFileSettingS
+}
+
+// RotateFileSettingS mirrors lumberjack's rotation knobs for the file sink.
+type RotateFileSettingS struct {
+	Enable     bool
+	SavePath   string
+	FileName   string
+	FileExt    string
+	MaxSize    int // megabytes
+	MaxBackups int
+	MaxAge     int // days
+	Compress   bool
+}
+
+// TracerSettingS configures the Jaeger tracer initialized in main: which
+// service name spans are reported under and where the Jaeger agent listens.
+type TracerSettingS struct {
+	ServiceName string
+	AgentHost   string
+	AgentPort   int
+}
+
+// LokiSettingS configures the optional Loki push sink: where to push to,
+// the static stream labels, and the batching/backpressure behaviour of the
+// bounded buffer that sits in front of the HTTP push.
+type LokiSettingS struct {
+	Enable bool
+	Host   string
+	Port   int
+	Source string
+	Job    string
+
+	// Labels lists the JSON fields to lift out of each log line and attach
+	// as Loki stream labels (e.g. "app", "env", "level") instead of leaving
+	// them buried in the line body; entries with differing values for any
+	// of these are pushed as separate streams. Defaults to
+	// []string{"app", "env", "level"} when empty.
+	Labels []string
+
+	BatchSize     int
+	FlushInterval time.Duration
+
+	BasicAuthUser     string
+	BasicAuthPassword string
+}
@@ -0,0 +1,45 @@
+package setting
+
+import (
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+type Setting struct {
+	vp *viper.Viper
+}
+
+func NewSetting(configs ...string) (*Setting, error) {
+	vp := viper.New()
+	vp.SetConfigName("config")
+	for _, config := range configs {
+		vp.AddConfigPath(config)
+	}
+	vp.SetConfigType("yaml")
+	if err := vp.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	s := &Setting{vp}
+	return s, nil
+}
+
+func (s *Setting) ReadSection(k string, v interface{}) error {
+	err := s.vp.UnmarshalKey(k, v)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Setting) WatchSettingChange() {
+	go func() {
+		s.vp.WatchConfig()
+		s.vp.OnConfigChange(func(in fsnotify.Event) {
+			_ = time.Now()
+		})
+	}()
+}
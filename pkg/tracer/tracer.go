@@ -0,0 +1,67 @@
+// Package tracer sets up the process-wide Jaeger tracer and provides the
+// small set of helpers other packages need to stay trace-aware without each
+// depending directly on opentracing/jaeger internals.
+package tracer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/opentracing/opentracing-go"
+	jaeger "github.com/uber/jaeger-client-go"
+	jaegercfg "github.com/uber/jaeger-client-go/config"
+
+	"github.com/xzy773599929/blog-service/pkg/setting"
+)
+
+// NewJaegerTracer builds a Jaeger tracer reporting to the agent described by
+// settings, registers it as the opentracing global tracer, and returns its
+// io.Closer so main can flush pending spans on shutdown.
+func NewJaegerTracer(settings *setting.TracerSettingS) (opentracing.Tracer, io.Closer, error) {
+	cfg := jaegercfg.Configuration{
+		ServiceName: settings.ServiceName,
+		Sampler: &jaegercfg.SamplerConfig{
+			Type:  jaeger.SamplerTypeConst,
+			Param: 1,
+		},
+		Reporter: &jaegercfg.ReporterConfig{
+			LogSpans:           true,
+			LocalAgentHostPort: fmt.Sprintf("%s:%d", settings.AgentHost, settings.AgentPort),
+		},
+	}
+
+	tracer, closer, err := cfg.NewTracer()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	opentracing.SetGlobalTracer(tracer)
+	return tracer, closer, nil
+}
+
+// TraceID returns the Jaeger trace id carried by ctx's active span, or ""
+// when ctx has no span (e.g. outside a traced request).
+func TraceID(ctx context.Context) string {
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return ""
+	}
+	sc, ok := span.Context().(jaeger.SpanContext)
+	if !ok {
+		return ""
+	}
+	return sc.TraceID().String()
+}
+
+// InjectHTTPHeaders propagates ctx's active span onto an outbound request so
+// the downstream service continues the same trace. Services building an
+// http.Client call should run every request through this before Do.
+func InjectHTTPHeaders(ctx context.Context, req *http.Request) {
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return
+	}
+	_ = opentracing.GlobalTracer().Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(req.Header))
+}
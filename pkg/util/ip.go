@@ -0,0 +1,26 @@
+package util
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ClientIP resolves the originating client IP for r, preferring the
+// X-Forwarded-For and X-Real-IP headers set by upstream proxies/load
+// balancers over the raw connection address.
+func ClientIP(r *http.Request) string {
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		if ip := strings.TrimSpace(strings.Split(xff, ",")[0]); ip != "" {
+			return ip
+		}
+	}
+	if xrip := r.Header.Get("X-Real-IP"); xrip != "" {
+		return xrip
+	}
+
+	ip := r.RemoteAddr
+	if idx := strings.LastIndex(ip, ":"); idx != -1 {
+		ip = ip[:idx]
+	}
+	return strings.Trim(ip, "[]")
+}
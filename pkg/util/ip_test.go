@@ -0,0 +1,58 @@
+package util
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name string
+		req  func() *http.Request
+		want string
+	}{
+		{
+			name: "X-Forwarded-For takes the first hop",
+			req: func() *http.Request {
+				r := &http.Request{Header: http.Header{}}
+				r.Header.Set("X-Forwarded-For", "203.0.113.1, 10.0.0.1")
+				return r
+			},
+			want: "203.0.113.1",
+		},
+		{
+			name: "falls back to X-Real-IP when no X-Forwarded-For",
+			req: func() *http.Request {
+				r := &http.Request{Header: http.Header{}}
+				r.Header.Set("X-Real-IP", "203.0.113.2")
+				return r
+			},
+			want: "203.0.113.2",
+		},
+		{
+			name: "falls back to RemoteAddr with port stripped",
+			req: func() *http.Request {
+				r := &http.Request{Header: http.Header{}, RemoteAddr: "203.0.113.3:54321"}
+				return r
+			},
+			want: "203.0.113.3",
+		},
+		{
+			name: "strips brackets from an IPv6 RemoteAddr",
+			req: func() *http.Request {
+				r := &http.Request{Header: http.Header{}, RemoteAddr: "[::1]:54321"}
+				return r
+			},
+			want: "::1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClientIP(tt.req())
+			if got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}